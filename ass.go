@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// AssStyle holds the styling knobs exposed on the CLI for karaoke subtitle
+// burn-in (--font, --font-size, --primary-color, --outline-color, --position).
+type AssStyle struct {
+	Font         string
+	FontSize     int
+	PrimaryColor string // hex, e.g. "FFFFFF" or "#FFFFFF"
+	OutlineColor string
+	Position     string // top|bottom|center
+}
+
+func GenerateAssFile(transcript *Schema, filename, tempDirPath string, style AssStyle) (string, error) {
+	// take in the gemini response and create a valid .ass file with
+	// word-level karaoke (\k) tags so players/ffmpeg highlight each
+	// word as it is spoken
+
+	var sb strings.Builder
+	sb.WriteString("[Script Info]\n")
+	sb.WriteString("ScriptType: v4.00+\n")
+	sb.WriteString("WrapStyle: 0\n")
+	sb.WriteString("ScaledBorderAndShadow: yes\n")
+	sb.WriteString("YCbCr Matrix: None\n\n")
+
+	sb.WriteString("[V4+ Styles]\n")
+	sb.WriteString("Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding\n")
+	sb.WriteString(fmt.Sprintf(
+		"Style: Karaoke,%s,%d,%s,&H000000FF,%s,&H00000000,0,0,0,0,100,100,0,0,1,2,0,%s,10,10,10,1\n\n",
+		style.Font, style.FontSize, hexToAssColor(style.PrimaryColor), hexToAssColor(style.OutlineColor), assAlignment(style.Position),
+	))
+
+	sb.WriteString("[Events]\n")
+	sb.WriteString("Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text\n")
+	for _, v := range transcript.Segments {
+		start := getAssTimestamp(v.Start)
+		end := getAssTimestamp(v.End)
+		sb.WriteString(fmt.Sprintf(
+			"Dialogue: 0,%s,%s,Karaoke,,0,0,0,,%s\n",
+			start, end, karaokeLine(v.Text, v.End-v.Start),
+		))
+	}
+
+	outputPath := filepath.Join(tempDirPath, filename+"_subs.ass")
+	err := os.WriteFile(outputPath, []byte(sb.String()), 0666)
+	if err != nil {
+		return "", err
+	}
+
+	// ffmpeg filter flag path is a pain in the ass, escape this stuff
+	escaped := strings.ReplaceAll(outputPath, `\`, `\\`)
+	escaped = strings.ReplaceAll(escaped, ":", "\\:")
+
+	return escaped, nil
+}
+
+// karaokeLine splits text into words and distributes the segment's duration
+// across them proportionally to each word's character count, emitting one
+// \k tag (in centiseconds) per word.
+func karaokeLine(text string, duration float64) string {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return ""
+	}
+
+	totalChars := 0
+	for _, w := range words {
+		totalChars += len(w)
+	}
+	if totalChars == 0 {
+		return ""
+	}
+
+	durationCs := duration * 100 // ASS karaoke tags are in centiseconds
+
+	var sb strings.Builder
+	for _, w := range words {
+		cs := int(durationCs * float64(len(w)) / float64(totalChars))
+		sb.WriteString(fmt.Sprintf(`{\k%d}%s `, cs, escapeAssText(w)))
+	}
+
+	return strings.TrimSpace(sb.String())
+}
+
+// escapeAssText strips ASS override-tag syntax (braces) and escapes
+// backslashes out of transcript text before it's embedded in a Dialogue
+// line, since a literal "{", "}", or "\" from the transcript would
+// otherwise corrupt the \k tag stream or bleed styling into later lines.
+func escapeAssText(text string) string {
+	text = strings.ReplaceAll(text, `\`, `\\`)
+	text = strings.ReplaceAll(text, "{", "")
+	text = strings.ReplaceAll(text, "}", "")
+	return text
+}
+
+func getAssTimestamp(time float64) string {
+	// ASS timestamps are h:mm:ss.cc (centiseconds, single-digit hour)
+	hour := int(time / 60 / 60)
+	min := fmt.Sprintf("%02d", int(time/60)%60)
+	sec := fmt.Sprintf("%02d", int(time)%60)
+	cs := fmt.Sprintf("%02d", int((time-float64(int(time)))*100))
+
+	return fmt.Sprintf("%d:%v:%v.%v", hour, min, sec, cs)
+}
+
+// hexToAssColor converts a "RRGGBB" (or "#RRGGBB") hex color into ASS's
+// &HAABBGGRR format.
+func hexToAssColor(hex string) string {
+	hex = strings.TrimPrefix(hex, "#")
+	if len(hex) != 6 {
+		return "&H00FFFFFF" // fall back to opaque white
+	}
+
+	r := hex[0:2]
+	g := hex[2:4]
+	b := hex[4:6]
+
+	return strings.ToUpper(fmt.Sprintf("&H00%s%s%s", b, g, r))
+}
+
+// assAlignment maps --position to an ASS numpad Alignment value.
+func assAlignment(position string) string {
+	switch position {
+	case "top":
+		return "8"
+	case "center":
+		return "5"
+	default: // "bottom"
+		return "2"
+	}
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"os/exec"
+
+	"github.com/urfave/cli/v3"
+)
+
+// Transcriber produces a Schema from a local audio file. TranscribeVideo
+// (Gemini) was the only implementation originally; it's now one of several
+// behind this interface so --backend can pick whisper.cpp, OpenAI, or
+// Deepgram instead.
+type Transcriber interface {
+	Transcribe(ctx context.Context, audioPath string) (Schema, error)
+}
+
+// NewTranscriber builds the Transcriber selected by --backend, reading
+// whatever credentials/paths that backend needs out of the environment.
+func NewTranscriber(c *cli.Command) (Transcriber, error) {
+	switch backend := c.String("backend"); backend {
+	case "gemini":
+		apiKey := os.Getenv("GOOGLE_API_KEY")
+		if apiKey == "" {
+			return nil, fmt.Errorf("GOOGLE_API_KEY environment variable is not set")
+		}
+		return geminiTranscriber{apiKey: apiKey, model: c.String("model")}, nil
+
+	case "whisper":
+		bin := os.Getenv("WHISPER_BIN")
+		if bin == "" {
+			return nil, fmt.Errorf("WHISPER_BIN environment variable is not set (path to a whisper.cpp binary)")
+		}
+		if _, err := exec.LookPath(bin); err != nil {
+			return nil, fmt.Errorf("%v\nWHISPER_BIN (%s) is not an executable on PATH", err.Error(), bin)
+		}
+		model := os.Getenv("WHISPER_MODEL")
+		if model == "" {
+			return nil, fmt.Errorf("WHISPER_MODEL environment variable is not set (path to a whisper.cpp ggml model)")
+		}
+		return whisperTranscriber{bin: bin, model: model}, nil
+
+	case "openai":
+		apiKey := os.Getenv("OPENAI_API_KEY")
+		if apiKey == "" {
+			return nil, fmt.Errorf("OPENAI_API_KEY environment variable is not set")
+		}
+		return openaiTranscriber{apiKey: apiKey}, nil
+
+	case "deepgram":
+		apiKey := os.Getenv("DEEPGRAM_API_KEY")
+		if apiKey == "" {
+			return nil, fmt.Errorf("DEEPGRAM_API_KEY environment variable is not set")
+		}
+		return deepgramTranscriber{apiKey: apiKey}, nil
+
+	default:
+		return nil, fmt.Errorf("%s is not a supported transcription backend", backend)
+	}
+}
+
+// whisperTranscriber shells out to a local whisper.cpp build, asking it for
+// JSON output on stdout and mapping that onto Schema. whisper.cpp doesn't
+// detect a language name the way Gemini does, so Language is left blank.
+type whisperTranscriber struct {
+	bin   string
+	model string
+}
+
+type whisperOutput struct {
+	Transcription []struct {
+		Offsets struct {
+			From int `json:"from"`
+			To   int `json:"to"`
+		} `json:"offsets"`
+		Text string `json:"text"`
+	} `json:"transcription"`
+}
+
+func (t whisperTranscriber) Transcribe(ctx context.Context, audioPath string) (Schema, error) {
+	// whisper.cpp's -of/--output-file is a path *prefix*, not a stdout
+	// sentinel: -oj makes it write "<prefix>.json" on disk, so point the
+	// prefix at a scratch file next to the audio and read that back.
+	outputPrefix := audioPath + "_whisper"
+	outputJSONPath := outputPrefix + ".json"
+	defer os.Remove(outputJSONPath)
+
+	cmd := exec.CommandContext(
+		ctx,
+		t.bin,
+		"-m", t.model,
+		"-f", audioPath,
+		"-oj", "-of", outputPrefix,
+	)
+	if err := cmd.Run(); err != nil {
+		return Schema{}, fmt.Errorf("%v\nerror while running whisper.cpp on %s", err.Error(), audioPath)
+	}
+
+	out, err := os.ReadFile(outputJSONPath)
+	if err != nil {
+		return Schema{}, fmt.Errorf("%v\nerror while reading whisper.cpp output %s", err.Error(), outputJSONPath)
+	}
+
+	var parsed whisperOutput
+	if err := json.Unmarshal(out, &parsed); err != nil {
+		return Schema{}, fmt.Errorf("%v\nerror while unmarshalling whisper.cpp output into json", err.Error())
+	}
+
+	var schema Schema
+	for _, seg := range parsed.Transcription {
+		schema.Segments = append(schema.Segments, struct {
+			Start float64 `json:"start"`
+			End   float64 `json:"end"`
+			Text  string  `json:"text"`
+		}{
+			Start: float64(seg.Offsets.From) / 1000,
+			End:   float64(seg.Offsets.To) / 1000,
+			Text:  seg.Text,
+		})
+	}
+
+	return schema, nil
+}
+
+// openaiTranscriber calls OpenAI's /v1/audio/transcriptions endpoint with
+// response_format=verbose_json, which returns Whisper-style segments.
+type openaiTranscriber struct {
+	apiKey string
+}
+
+type openaiResponse struct {
+	Language string `json:"language"`
+	Segments []struct {
+		Start float64 `json:"start"`
+		End   float64 `json:"end"`
+		Text  string  `json:"text"`
+	} `json:"segments"`
+}
+
+func (t openaiTranscriber) Transcribe(ctx context.Context, audioPath string) (Schema, error) {
+	file, err := os.Open(audioPath)
+	if err != nil {
+		return Schema{}, fmt.Errorf("%v\nerror while opening %s for upload to openai", err.Error(), audioPath)
+	}
+	defer file.Close()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := writer.WriteField("model", "whisper-1"); err != nil {
+		return Schema{}, err
+	}
+	if err := writer.WriteField("response_format", "verbose_json"); err != nil {
+		return Schema{}, err
+	}
+	part, err := writer.CreateFormFile("file", audioPath)
+	if err != nil {
+		return Schema{}, err
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return Schema{}, err
+	}
+	if err := writer.Close(); err != nil {
+		return Schema{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.openai.com/v1/audio/transcriptions", &body)
+	if err != nil {
+		return Schema{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+t.apiKey)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return Schema{}, fmt.Errorf("%v\nerror while calling openai transcriptions api", err.Error())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Schema{}, fmt.Errorf("openai transcriptions api returned status %d", resp.StatusCode)
+	}
+
+	var parsed openaiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return Schema{}, fmt.Errorf("%v\nerror while unmarshalling openai response into json", err.Error())
+	}
+
+	schema := Schema{Language: parsed.Language}
+	for _, seg := range parsed.Segments {
+		schema.Segments = append(schema.Segments, struct {
+			Start float64 `json:"start"`
+			End   float64 `json:"end"`
+			Text  string  `json:"text"`
+		}{Start: seg.Start, End: seg.End, Text: seg.Text})
+	}
+
+	return schema, nil
+}
+
+// deepgramTranscriber calls Deepgram's prerecorded audio API with
+// utterances enabled so the response can be mapped onto Schema.Segments.
+type deepgramTranscriber struct {
+	apiKey string
+}
+
+type deepgramResponse struct {
+	Results struct {
+		Utterances []struct {
+			Start      float64 `json:"start"`
+			End        float64 `json:"end"`
+			Transcript string  `json:"transcript"`
+		} `json:"utterances"`
+	} `json:"results"`
+}
+
+func (t deepgramTranscriber) Transcribe(ctx context.Context, audioPath string) (Schema, error) {
+	file, err := os.Open(audioPath)
+	if err != nil {
+		return Schema{}, fmt.Errorf("%v\nerror while opening %s for upload to deepgram", err.Error(), audioPath)
+	}
+	defer file.Close()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.deepgram.com/v1/listen?model=nova-2&utterances=true", file)
+	if err != nil {
+		return Schema{}, err
+	}
+	req.Header.Set("Authorization", "Token "+t.apiKey)
+	req.Header.Set("Content-Type", "audio/mpeg")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return Schema{}, fmt.Errorf("%v\nerror while calling deepgram prerecorded api", err.Error())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Schema{}, fmt.Errorf("deepgram prerecorded api returned status %d", resp.StatusCode)
+	}
+
+	var parsed deepgramResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return Schema{}, fmt.Errorf("%v\nerror while unmarshalling deepgram response into json", err.Error())
+	}
+
+	var schema Schema
+	for _, utterance := range parsed.Results.Utterances {
+		schema.Segments = append(schema.Segments, struct {
+			Start float64 `json:"start"`
+			End   float64 `json:"end"`
+			Text  string  `json:"text"`
+		}{Start: utterance.Start, End: utterance.End, Text: utterance.Transcript})
+	}
+
+	return schema, nil
+}
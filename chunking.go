@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// audioChunk is one overlapping slice of a long audio file produced by
+// splitAudio. StartOffset gets added back onto every segment's Start/End
+// after transcription so timestamps line up with the original audio.
+// OwnedStart/OwnedEnd mark the non-overlapping region this chunk is
+// responsible for, used to de-duplicate segments that fall in the overlap.
+type audioChunk struct {
+	Index       int
+	AudioPath   string
+	StartOffset float64
+	OwnedStart  float64
+	OwnedEnd    float64
+}
+
+// ProbeAudioDuration asks ffprobe for the duration (in seconds) of an audio file.
+func ProbeAudioDuration(audioPath string) (float64, error) {
+	cmd := exec.Command(
+		"ffprobe",
+		"-v", "error",
+		"-show_entries", "format=duration",
+		"-of", "default=noprint_wrappers=1:nokey=1",
+		audioPath,
+	)
+	out, err := cmd.Output()
+	if err != nil {
+		return 0, fmt.Errorf("%v\nerror while probing duration of %s", err.Error(), audioPath)
+	}
+
+	duration, err := strconv.ParseFloat(strings.TrimSpace(string(out)), 64)
+	if err != nil {
+		return 0, fmt.Errorf("%v\nerror while parsing ffprobe duration output", err.Error())
+	}
+
+	return duration, nil
+}
+
+// splitAudio cuts audioPath into consecutive chunkDuration-sized pieces with
+// a small overlap on each side so Gemini always has context across a cut,
+// returning one audioChunk per piece.
+func splitAudio(audioPath, tempDirPath string, totalDuration, chunkDuration, overlap float64) ([]audioChunk, error) {
+	var chunks []audioChunk
+	for i, ownedStart := 0, 0.0; ownedStart < totalDuration; i, ownedStart = i+1, ownedStart+chunkDuration {
+		ownedEnd := ownedStart + chunkDuration
+		if ownedEnd > totalDuration {
+			ownedEnd = totalDuration
+		}
+
+		actualStart := ownedStart - overlap
+		if actualStart < 0 {
+			actualStart = 0
+		}
+		actualEnd := ownedEnd + overlap
+		if actualEnd > totalDuration {
+			actualEnd = totalDuration
+		}
+
+		chunkPath := filepath.Join(tempDirPath, fmt.Sprintf("chunk_%d.mp3", i))
+		cmd := exec.Command(
+			"ffmpeg",
+			"-y",
+			"-ss", fmt.Sprintf("%f", actualStart),
+			"-t", fmt.Sprintf("%f", actualEnd-actualStart),
+			"-i", audioPath,
+			chunkPath,
+		)
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("%v\nerror while splitting chunk %d from %s", err.Error(), i, audioPath)
+		}
+
+		chunks = append(chunks, audioChunk{
+			Index:       i,
+			AudioPath:   chunkPath,
+			StartOffset: actualStart,
+			OwnedStart:  ownedStart,
+			OwnedEnd:    ownedEnd,
+		})
+	}
+
+	return chunks, nil
+}
+
+// TranscribeChunked probes audioPath's duration and, if it exceeds
+// chunkDuration, splits it into overlapping chunks, transcribes each chunk
+// concurrently, and stitches the resulting segments back into one Schema
+// with chunk offsets applied and overlap duplicates removed according to
+// stitchStrategy ("midpoint" or "longest-text").
+func TranscribeChunked(ctx context.Context, transcriber Transcriber, audioPath, tempDirPath string, chunkDuration, overlap time.Duration, stitchStrategy string, maxRetries int, retryBackoff time.Duration) (Schema, error) {
+	duration, err := ProbeAudioDuration(audioPath)
+	if err != nil {
+		return Schema{}, err
+	}
+	if duration <= chunkDuration.Seconds() {
+		return transcribeWithRetry(ctx, transcriber, audioPath, maxRetries, retryBackoff)
+	}
+
+	chunks, err := splitAudio(audioPath, tempDirPath, duration, chunkDuration.Seconds(), overlap.Seconds())
+	if err != nil {
+		return Schema{}, err
+	}
+
+	type chunkResult struct {
+		chunk  audioChunk
+		schema Schema
+		err    error
+	}
+	results := make([]chunkResult, len(chunks))
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func(i int, chunk audioChunk) {
+			defer wg.Done()
+			schema, err := transcribeWithRetry(ctx, transcriber, chunk.AudioPath, maxRetries, retryBackoff)
+			results[i] = chunkResult{chunk: chunk, schema: schema, err: err}
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	var merged Schema
+	for _, r := range results {
+		if r.err != nil {
+			return Schema{}, fmt.Errorf("%v\nerror while transcribing chunk %d", r.err.Error(), r.chunk.Index)
+		}
+		if merged.Language == "" {
+			merged.Language = r.schema.Language
+		}
+
+		for _, seg := range r.schema.Segments {
+			seg.Start += r.chunk.StartOffset
+			seg.End += r.chunk.StartOffset
+
+			if stitchStrategy == "midpoint" {
+				midpoint := (seg.Start + seg.End) / 2
+				if midpoint < r.chunk.OwnedStart || midpoint >= r.chunk.OwnedEnd {
+					continue // this segment belongs to a neighboring chunk's owned region
+				}
+			}
+
+			merged.Segments = append(merged.Segments, seg)
+		}
+	}
+
+	sort.Slice(merged.Segments, func(i, j int) bool {
+		return merged.Segments[i].Start < merged.Segments[j].Start
+	})
+
+	if stitchStrategy == "longest-text" {
+		var deduped []struct {
+			Start float64 `json:"start"`
+			End   float64 `json:"end"`
+			Text  string  `json:"text"`
+		}
+		for _, seg := range merged.Segments {
+			if len(deduped) > 0 && seg.Start < deduped[len(deduped)-1].End {
+				// overlaps the previously kept segment; keep whichever has more text
+				if len(seg.Text) > len(deduped[len(deduped)-1].Text) {
+					deduped[len(deduped)-1] = seg
+				}
+				continue
+			}
+			deduped = append(deduped, seg)
+		}
+		merged.Segments = deduped
+	}
+
+	return merged, nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// TranscriptWriter renders a transcript into a specific on-disk format so
+// new export formats can be added without touching the export/transcribe
+// plumbing.
+type TranscriptWriter interface {
+	// Ext is the file extension (no dot) this writer produces, e.g. "vtt".
+	Ext() string
+	// Render returns the fully formatted transcript contents.
+	Render(transcript *Schema) ([]byte, error)
+}
+
+var transcriptWriters = map[string]TranscriptWriter{
+	"srt":  srtWriter{},
+	"vtt":  vttWriter{},
+	"json": jsonWriter{},
+	"tsv":  tsvWriter{},
+	"txt":  txtWriter{},
+}
+
+// ExportTranscripts writes the transcript out in every requested --export
+// format (srt, vtt, json, tsv, txt) next to the output video.
+func ExportTranscripts(transcript *Schema, destDir, baseName string, formats []string) error {
+	for _, format := range formats {
+		writer, ok := transcriptWriters[format]
+		if !ok {
+			return fmt.Errorf("%s is not a supported export format", format)
+		}
+
+		contents, err := writer.Render(transcript)
+		if err != nil {
+			return fmt.Errorf("%v\nerror while rendering %s transcript", err.Error(), format)
+		}
+
+		outputPath := filepath.Join(destDir, baseName+"."+writer.Ext())
+		if err := os.WriteFile(outputPath, contents, 0666); err != nil {
+			return fmt.Errorf("%v\nerror while writing %s transcript", err.Error(), format)
+		}
+	}
+
+	return nil
+}
+
+type srtWriter struct{}
+
+func (srtWriter) Ext() string { return "srt" }
+
+func (srtWriter) Render(transcript *Schema) ([]byte, error) {
+	var sb strings.Builder
+	for i, v := range transcript.Segments {
+		sb.WriteString(strconv.Itoa(i+1) + "\n")
+		sb.WriteString(fmt.Sprintf("%v --> %v\n", getTimestamp(v.Start), getTimestamp(v.End)))
+		sb.WriteString("- " + v.Text + "\n")
+	}
+	return []byte(sb.String()), nil
+}
+
+type vttWriter struct{}
+
+func (vttWriter) Ext() string { return "vtt" }
+
+func (vttWriter) Render(transcript *Schema) ([]byte, error) {
+	var sb strings.Builder
+	sb.WriteString("WEBVTT\n\n")
+	for _, v := range transcript.Segments {
+		sb.WriteString(fmt.Sprintf("%s --> %s\n", getVttTimestamp(v.Start), getVttTimestamp(v.End)))
+		sb.WriteString(v.Text + "\n\n")
+	}
+	return []byte(sb.String()), nil
+}
+
+// getVttTimestamp reuses the SRT timestamp math but swaps the "," millisecond
+// separator for VTT's ".".
+func getVttTimestamp(time float64) string {
+	return strings.Replace(getTimestamp(time), ",", ".", 1)
+}
+
+type jsonWriter struct{}
+
+func (jsonWriter) Ext() string { return "json" }
+
+func (jsonWriter) Render(transcript *Schema) ([]byte, error) {
+	// full Schema (language + segments) so downstream tools get a
+	// machine-readable transcript, not just the subtitle text
+	return json.MarshalIndent(transcript, "", "  ")
+}
+
+type tsvWriter struct{}
+
+func (tsvWriter) Ext() string { return "tsv" }
+
+func (tsvWriter) Render(transcript *Schema) ([]byte, error) {
+	var sb strings.Builder
+	sb.WriteString("start\tend\ttext\n")
+	for _, v := range transcript.Segments {
+		sb.WriteString(fmt.Sprintf("%v\t%v\t%s\n", v.Start, v.End, v.Text))
+	}
+	return []byte(sb.String()), nil
+}
+
+type txtWriter struct{}
+
+func (txtWriter) Ext() string { return "txt" }
+
+func (txtWriter) Render(transcript *Schema) ([]byte, error) {
+	var sb strings.Builder
+	for _, v := range transcript.Segments {
+		sb.WriteString(v.Text + "\n")
+	}
+	return []byte(sb.String()), nil
+}
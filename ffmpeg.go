@@ -7,51 +7,105 @@ import (
 	"strings"
 )
 
-func VideoToMp3(tempDirPath, fileinputPath string) (string, error) {
-	// takes a video input and exports the mp3 audio
-	filename := filepath.Base(fileinputPath)
-	outputAudioPath := filepath.Join(tempDirPath, filename+"_audio.mp3")
+// EncodeOptions controls how ffmpeg decodes/encodes video across
+// VideoToMp3 and ApplySubtitles: which hardware accelerator to use (if
+// any), quality/speed tuning, and whether to print the composed command
+// instead of running it.
+type EncodeOptions struct {
+	HWAccel HWAccel
+	CRF     int
+	Preset  string
+	DryRun  bool
+}
 
-	cmd := exec.Command(
-		"ffmpeg",
-		"-y", // this will overwrite the output video if already exists
-		"-i", fileinputPath,
-		outputAudioPath,
-	)
+// runFfmpeg executes an ffmpeg invocation, or just prints it to stderr and
+// returns nil when opts.DryRun is set, so users can sanity-check the
+// hwaccel/crf/preset flags being composed before committing to a real run.
+func runFfmpeg(args []string, opts EncodeOptions) error {
+	if opts.DryRun {
+		fmt.Println("ffmpeg " + strings.Join(args, " "))
+		return nil
+	}
 
-	// better performance
+	cmd := exec.Command("ffmpeg", args...)
 	cmd.Stdout = nil
 	cmd.Stderr = nil
+	return cmd.Run()
+}
 
-	err := cmd.Run()
-	if err != nil {
+// runFfmpegWithFallback runs the ffmpeg command buildArgs composes for
+// opts.HWAccel. ffmpeg -encoders only reports which encoders a build was
+// compiled with, not which ones have working hardware behind them, so a
+// hwaccel encode can still fail at runtime (no GPU, driver mismatch, etc.);
+// when that happens, retry once with software encoding before giving up.
+func runFfmpegWithFallback(buildArgs func(HWAccel) []string, opts EncodeOptions) error {
+	err := runFfmpeg(buildArgs(opts.HWAccel), opts)
+	if err == nil || opts.DryRun || opts.HWAccel.Name == "" {
+		return err
+	}
+
+	return runFfmpeg(buildArgs(HWAccel{}), opts)
+}
+
+func VideoToMp3(tempDirPath, fileinputPath string, opts EncodeOptions) (string, error) {
+	// takes a video input and exports the mp3 audio
+	filename := filepath.Base(fileinputPath)
+	outputAudioPath := filepath.Join(tempDirPath, filename+"_audio.mp3")
+
+	buildArgs := func(hwaccel HWAccel) []string {
+		args := []string{"-y"} // this will overwrite the output video if already exists
+		args = append(args, hwaccel.HWAccelArgs...)
+		args = append(args, "-i", fileinputPath, outputAudioPath)
+		return args
+	}
+
+	if err := runFfmpegWithFallback(buildArgs, opts); err != nil {
 		return "", fmt.Errorf("%v\nerror while converting %s to audio format", err.Error(), fileinputPath)
 	}
 
 	return outputAudioPath, nil
 }
 
-func ApplySubtitles(tempDirPath, fileinputPath, strFilePath string) (string, error) {
-	// takes in an srt file and applies the subtitle text over the original video
+func ApplySubtitles(tempDirPath, fileinputPath, subsFilePath, format string, opts EncodeOptions) (string, error) {
+	// takes in a subtitle file (.srt or .ass) and burns the subtitle text
+	// over the original video, switching the ffmpeg filter to match
 	finalVideoPath := "transcribed_" + filepath.Base(fileinputPath)
 	tempVideoPath := filepath.Join(tempDirPath, finalVideoPath)
 
 	// ffmpeg filter flag path is a pain in the ass, escape this stuff
-	escaped := strings.ReplaceAll(strFilePath, `\`, `\\`)
+	escaped := strings.ReplaceAll(subsFilePath, `\`, `\\`)
 	escaped = strings.ReplaceAll(escaped, ":", "\\:")
 
-	cmd := exec.Command("ffmpeg",
-		"-y",
-		"-i", fileinputPath,
-		"-vf", fmt.Sprintf("subtitles='%s'", escaped),
-		tempVideoPath, // place in tmp folder
-	)
-	// better performance
-	cmd.Stdout = nil
-	cmd.Stderr = nil
+	filter := fmt.Sprintf("subtitles='%s'", escaped)
+	if format == "ass" {
+		filter = fmt.Sprintf("ass='%s'", escaped)
+	}
+
+	buildArgs := func(hwaccel HWAccel) []string {
+		args := []string{"-y"}
+		args = append(args, hwaccel.HWAccelArgs...)
+		args = append(args, "-i", fileinputPath, "-vf", filter)
+
+		if hwaccel.VideoCodec != "" {
+			args = append(args, "-c:v", hwaccel.VideoCodec)
+		}
+		if opts.Preset != "" {
+			args = append(args, "-preset", opts.Preset)
+		}
+		if opts.CRF > 0 {
+			// libx264/libx265 take -crf directly; every GPU encoder in
+			// hwaccelCandidates uses a different flag for the same idea.
+			qualityFlag := "-crf"
+			if hwaccel.QualityFlag != "" {
+				qualityFlag = hwaccel.QualityFlag
+			}
+			args = append(args, qualityFlag, fmt.Sprint(opts.CRF))
+		}
+
+		return append(args, tempVideoPath) // place in tmp folder
+	}
 
-	err := cmd.Run()
-	if err != nil {
+	if err := runFfmpegWithFallback(buildArgs, opts); err != nil {
 		return "", fmt.Errorf("%v\nerror while adding subtitles to original video", err.Error())
 	}
 
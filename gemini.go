@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 
-	"github.com/urfave/cli/v3"
 	"google.golang.org/genai"
 )
 
@@ -40,7 +39,18 @@ var TranscriptSchema = &genai.Schema{
 	Required: []string{"language", "segments"},
 }
 
-func TranscribeVideo(ctx context.Context, apiKey string, c *cli.Command) (Schema, error) {
+// geminiTranscriber implements Transcriber against Google Gemini, the
+// original (and still default) transcription backend.
+type geminiTranscriber struct {
+	apiKey string
+	model  string
+}
+
+func (t geminiTranscriber) Transcribe(ctx context.Context, audioPath string) (Schema, error) {
+	return TranscribeVideo(ctx, t.apiKey, t.model, audioPath)
+}
+
+func TranscribeVideo(ctx context.Context, apiKey, model, localAudioPath string) (Schema, error) {
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
 		APIKey: apiKey,
 	})
@@ -48,7 +58,6 @@ func TranscribeVideo(ctx context.Context, apiKey string, c *cli.Command) (Schema
 		return Schema{}, fmt.Errorf("error while creating gemini client")
 	}
 
-	localAudioPath := "output.mp3"
 	uploadedFile, err := client.Files.UploadFromPath(
 		ctx,
 		localAudioPath,
@@ -66,7 +75,6 @@ func TranscribeVideo(ctx context.Context, apiKey string, c *cli.Command) (Schema
 		genai.NewContentFromParts(parts, genai.RoleUser),
 	}
 
-	model := c.String("model")
 	result, err := client.Models.GenerateContent(
 		ctx,
 		fmt.Sprintf("gemini-3-%v-preview", model),
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// GrammarChecker corrects a piece of transcript text, using the detected
+// Schema.Language to pick the right dictionary/rules.
+type GrammarChecker interface {
+	Check(text, language string) (string, error)
+}
+
+// NewGrammarChecker picks a LanguageTool HTTP checker when --grammar-server
+// is set, otherwise falls back to whichever local binary is on PATH.
+func NewGrammarChecker(server string) (GrammarChecker, error) {
+	if server != "" {
+		return languageToolChecker{server: server}, nil
+	}
+
+	for _, bin := range []string{"grammalecte-cli", "hunspell"} {
+		if path, err := exec.LookPath(bin); err == nil {
+			return localBinaryChecker{bin: path}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("--grammar-check was set but no --grammar-server was given and no local grammar checker (grammalecte-cli, hunspell) was found on PATH")
+}
+
+// ApplyGrammarCheck runs every segment's text through checker, correcting
+// the segment in-place and printing a diff to stderr for anything it changes.
+func ApplyGrammarCheck(transcript *Schema, checker GrammarChecker) error {
+	for i := range transcript.Segments {
+		seg := &transcript.Segments[i]
+
+		corrected, err := checker.Check(seg.Text, transcript.Language)
+		if err != nil {
+			return fmt.Errorf("%v\nerror while grammar-checking segment", err.Error())
+		}
+		if corrected == seg.Text {
+			continue
+		}
+
+		fmt.Fprintf(os.Stderr, "- %s\n+ %s\n", seg.Text, corrected)
+		seg.Text = corrected
+	}
+
+	return nil
+}
+
+// languageToolChecker talks to a LanguageTool-compatible HTTP server
+// (https://dev.languagetool.org/http-server) and applies its top-ranked
+// replacement for each match it reports.
+type languageToolChecker struct {
+	server string
+}
+
+type languageToolMatch struct {
+	Offset       int `json:"offset"`
+	Length       int `json:"length"`
+	Replacements []struct {
+		Value string `json:"value"`
+	} `json:"replacements"`
+}
+
+type languageToolResponse struct {
+	Matches []languageToolMatch `json:"matches"`
+}
+
+func (c languageToolChecker) Check(text, language string) (string, error) {
+	form := url.Values{
+		"text":     {text},
+		"language": {languageToolCode(language)},
+	}
+
+	resp, err := http.PostForm(strings.TrimRight(c.server, "/")+"/v2/check", form)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var parsed languageToolResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return "", err
+	}
+
+	// LanguageTool's offset/length are counted in runes, not bytes, so slice
+	// a []rune rather than the UTF-8 string directly or multi-byte
+	// characters (accents, etc.) get split mid-character.
+	runes := []rune(text)
+	// apply corrections back-to-front so earlier offsets stay valid
+	for i := len(parsed.Matches) - 1; i >= 0; i-- {
+		m := parsed.Matches[i]
+		if len(m.Replacements) == 0 {
+			continue
+		}
+		replacement := []rune(m.Replacements[0].Value)
+		tail := append([]rune{}, runes[m.Offset+m.Length:]...)
+		runes = append(runes[:m.Offset], append(replacement, tail...)...)
+	}
+
+	return string(runes), nil
+}
+
+// languageToolCode maps Schema.Language's free-form model output to the
+// language code LanguageTool expects, falling back to auto-detection.
+func languageToolCode(language string) string {
+	switch strings.ToLower(language) {
+	case "english", "en":
+		return "en-US"
+	case "spanish", "es":
+		return "es"
+	case "french", "fr":
+		return "fr"
+	case "german", "de":
+		return "de"
+	default:
+		return "auto"
+	}
+}
+
+// localBinaryChecker shells out to a local spellchecker (grammalecte-cli,
+// hunspell) via exec.LookPath. These tools flag issues rather than rewrite
+// sentences in pipe mode, so without a --grammar-server we can only report
+// what they find, not apply a fix.
+type localBinaryChecker struct {
+	bin string
+}
+
+func (c localBinaryChecker) Check(text, language string) (string, error) {
+	cmd := exec.Command(c.bin, "-a")
+	cmd.Stdin = bytes.NewBufferString(text)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%v\nerror while running local grammar checker %s", err.Error(), c.bin)
+	}
+
+	if len(out) > 0 {
+		fmt.Fprintf(os.Stderr, "grammar checker flagged issues in: %s\n%s", text, out)
+	}
+
+	return text, nil
+}
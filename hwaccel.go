@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// HWAccel describes the decode/encode pipeline ffmpeg should use for a
+// video: which -hwaccel to request and which -c:v encoder pairs with it.
+// The zero value means software (libx264) encoding.
+type HWAccel struct {
+	Name        string   // "nvenc", "qsv", "vt", "vaapi", "amf", or "" for software
+	HWAccelArgs []string // ffmpeg args placed before -i, e.g. -hwaccel cuda
+	VideoCodec  string   // -c:v value, e.g. h264_nvenc
+	QualityFlag string   // the --crf equivalent this encoder accepts, e.g. -cq
+}
+
+// hwaccelCandidates are checked in priority order against the encoders
+// ffmpeg reports as compiled in, since more than one may be available on a
+// machine (e.g. vaapi and qsv both present on Linux+Intel). None of these
+// GPU encoders accept libx264/libx265's -crf flag, so each carries the
+// quality flag it actually understands instead.
+var hwaccelCandidates = []HWAccel{
+	{Name: "nvenc", HWAccelArgs: []string{"-hwaccel", "cuda"}, VideoCodec: "h264_nvenc", QualityFlag: "-cq"},
+	{Name: "qsv", HWAccelArgs: []string{"-hwaccel", "qsv"}, VideoCodec: "h264_qsv", QualityFlag: "-global_quality"},
+	{Name: "vt", HWAccelArgs: []string{"-hwaccel", "videotoolbox"}, VideoCodec: "h264_videotoolbox", QualityFlag: "-q:v"},
+	{Name: "vaapi", HWAccelArgs: []string{"-hwaccel", "vaapi"}, VideoCodec: "h264_vaapi", QualityFlag: "-qp"},
+	{Name: "amf", HWAccelArgs: []string{"-hwaccel", "d3d11va"}, VideoCodec: "h264_amf", QualityFlag: "-qp"},
+}
+
+var (
+	hwaccelProbeOnce   sync.Once
+	hwaccelProbeResult HWAccel
+)
+
+// ResolveHWAccel turns --hwaccel's value into a concrete HWAccel. "none"
+// forces software encoding, a named backend is used as-is (so a user can
+// override misdetection), and "auto" probes installed ffmpeg encoders once
+// per process and picks the first match.
+func ResolveHWAccel(requested string) (HWAccel, error) {
+	switch requested {
+	case "none":
+		return HWAccel{}, nil
+	case "auto":
+		hwaccelProbeOnce.Do(func() {
+			hwaccelProbeResult = detectHWAccel()
+		})
+		return hwaccelProbeResult, nil
+	}
+
+	for _, candidate := range hwaccelCandidates {
+		if candidate.Name == requested {
+			return candidate, nil
+		}
+	}
+
+	return HWAccel{}, fmt.Errorf("%s is not a supported hwaccel", requested)
+}
+
+// detectHWAccel asks ffmpeg which encoders it was built with and picks the
+// first GPU encoder from hwaccelCandidates that's present, falling back to
+// software encoding if none are (or ffmpeg can't be probed at all).
+func detectHWAccel() HWAccel {
+	out, err := exec.Command("ffmpeg", "-hide_banner", "-encoders").Output()
+	if err != nil {
+		return HWAccel{}
+	}
+
+	available := string(out)
+	for _, candidate := range hwaccelCandidates {
+		if strings.Contains(available, candidate.VideoCodec) {
+			return candidate
+		}
+	}
+
+	return HWAccel{}
+}
@@ -9,6 +9,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"slices"
 	"strings"
 	"sync"
@@ -42,6 +43,165 @@ func main() {
 					return nil
 				},
 			},
+			&cli.StringFlag{
+				Name:        "format",
+				Usage:       "Subtitle format to burn into the video: srt or ass (word-by-word karaoke)",
+				Value:       "srt",
+				DefaultText: "srt",
+				Required:    false,
+				Validator: func(s string) error {
+					if s != "srt" && s != "ass" {
+						return fmt.Errorf("%s is not a valid format", s)
+					}
+					return nil
+				},
+			},
+			&cli.StringFlag{
+				Name:     "font",
+				Usage:    "Font used for --format ass karaoke subtitles",
+				Value:    "Arial",
+				Required: false,
+			},
+			&cli.IntFlag{
+				Name:     "font-size",
+				Usage:    "Font size used for --format ass karaoke subtitles",
+				Value:    36,
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "primary-color",
+				Usage:    "Hex color (e.g. FFFFFF) of the highlighted karaoke word for --format ass",
+				Value:    "FFFFFF",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "outline-color",
+				Usage:    "Hex color (e.g. 000000) of the karaoke text outline for --format ass",
+				Value:    "000000",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:        "position",
+				Usage:       "Where to place burned-in subtitles: top, bottom, or center",
+				Value:       "bottom",
+				DefaultText: "bottom",
+				Required:    false,
+				Validator: func(s string) error {
+					if s != "top" && s != "bottom" && s != "center" {
+						return fmt.Errorf("%s is not a valid position", s)
+					}
+					return nil
+				},
+			},
+			&cli.StringFlag{
+				Name:     "export",
+				Usage:    "Comma-separated transcript formats to save next to the output video (srt,vtt,json,tsv,txt)",
+				Required: false,
+				Validator: func(s string) error {
+					for _, format := range parseExportFormats(s) {
+						if _, ok := transcriptWriters[format]; !ok {
+							return fmt.Errorf("%s is not a supported export format", format)
+						}
+					}
+					return nil
+				},
+			},
+			&cli.BoolFlag{
+				Name:     "grammar-check",
+				Usage:    "Run a grammar/spell-check pass over the transcript before generating subtitles",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "grammar-server",
+				Usage:    "LanguageTool HTTP server to use for --grammar-check (falls back to a local grammalecte/hunspell binary on PATH)",
+				Required: false,
+			},
+			&cli.IntFlag{
+				Name:        "concurrency",
+				Usage:       "Max number of videos to transcode/transcribe at once when --input is a directory",
+				Value:       runtime.NumCPU(),
+				DefaultText: "number of CPUs",
+				Required:    false,
+			},
+			&cli.IntFlag{
+				Name:     "max-retries",
+				Usage:    "Number of times to retry a failed transcription backend call before giving up on a file",
+				Value:    3,
+				Required: false,
+			},
+			&cli.DurationFlag{
+				Name:     "retry-backoff",
+				Usage:    "Base delay between transcription backend retries, doubled after each attempt",
+				Value:    2 * time.Second,
+				Required: false,
+			},
+			&cli.DurationFlag{
+				Name:     "chunk-duration",
+				Usage:    "Split audio longer than this into overlapping chunks before transcribing, since some backends get unreliable on multi-hour inputs",
+				Value:    20 * time.Minute,
+				Required: false,
+			},
+			&cli.DurationFlag{
+				Name:     "chunk-overlap",
+				Usage:    "How much audio each chunk shares with its neighbors, used to stitch segments back together across a chunk boundary",
+				Value:    2 * time.Second,
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:        "stitch-strategy",
+				Usage:       "How to resolve duplicate segments in a chunk overlap: midpoint or longest-text",
+				Value:       "midpoint",
+				DefaultText: "midpoint",
+				Required:    false,
+				Validator: func(s string) error {
+					if s != "midpoint" && s != "longest-text" {
+						return fmt.Errorf("%s is not a valid stitch strategy", s)
+					}
+					return nil
+				},
+			},
+			&cli.StringFlag{
+				Name:        "backend",
+				Usage:       "Transcription backend to use: gemini, whisper (local whisper.cpp), openai, or deepgram",
+				Value:       "gemini",
+				DefaultText: "gemini",
+				Required:    false,
+				Validator: func(s string) error {
+					if s != "gemini" && s != "whisper" && s != "openai" && s != "deepgram" {
+						return fmt.Errorf("%s is not a supported transcription backend", s)
+					}
+					return nil
+				},
+			},
+			&cli.StringFlag{
+				Name:        "hwaccel",
+				Usage:       "GPU encoder to use for ffmpeg: auto, none, nvenc, qsv, vt, vaapi, or amf",
+				Value:       "auto",
+				DefaultText: "auto",
+				Required:    false,
+				Validator: func(s string) error {
+					if s != "auto" && s != "none" && s != "nvenc" && s != "qsv" && s != "vt" && s != "vaapi" && s != "amf" {
+						return fmt.Errorf("%s is not a supported hwaccel", s)
+					}
+					return nil
+				},
+			},
+			&cli.IntFlag{
+				Name:     "crf",
+				Usage:    "Constant rate factor passed to ffmpeg's video encoder (lower is higher quality); 0 leaves it unset",
+				Value:    0,
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "preset",
+				Usage:    "ffmpeg encoder preset (e.g. fast, medium, slow); empty leaves it unset",
+				Required: false,
+			},
+			&cli.BoolFlag{
+				Name:     "dry-run-ffmpeg",
+				Usage:    "Print the composed ffmpeg commands instead of running them",
+				Required: false,
+			},
 		},
 		Action: func(ctx context.Context, c *cli.Command) error {
 			// ensure user has ffmpeg installed
@@ -56,30 +216,52 @@ func main() {
 				)
 			}
 
-			// load env variable (need gemini api key to work)
+			hwaccel, err := ResolveHWAccel(c.String("hwaccel"))
+			if err != nil {
+				return err
+			}
+			encodeOpts := EncodeOptions{
+				HWAccel: hwaccel,
+				CRF:     c.Int("crf"),
+				Preset:  c.String("preset"),
+				DryRun:  c.Bool("dry-run-ffmpeg"),
+			}
+
+			inputPath := c.String("input")
+
+			// --dry-run-ffmpeg only wants to see the composed commands, so
+			// skip everything that requires a working backend/transcript:
+			// no .env load, no credential check, no probing/transcribing.
+			if encodeOpts.DryRun {
+				return dryRunFfmpeg(inputPath, c, encodeOpts)
+			}
+
+			// load env variables (backends read their own API keys out of these)
 			err = godotenv.Load()
 			if err != nil {
 				return fmt.Errorf("error loading .env file in current directory")
 			}
-			apiKey := os.Getenv("GOOGLE_API_KEY")
-			if apiKey == "" {
-				return fmt.Errorf("GOOGLE_API_KEY is not set")
+			transcriber, err := NewTranscriber(c)
+			if err != nil {
+				return err
 			}
 
-			inputPath := c.String("input")
 			info, err := os.Stat(inputPath)
 			if err != nil {
 				return errors.New("unable to read input path: " + inputPath)
 			}
 			if info.IsDir() {
 				// many videos
-				err := transcribeDir(inputPath, apiKey, ctx, c)
+				summary, err := transcribeDir(inputPath, transcriber, ctx, c, encodeOpts)
 				if err != nil {
 					return err
 				}
+				if summary.Failed > 0 {
+					return fmt.Errorf("%d of %d video(s) failed to transcribe", summary.Failed, summary.Total)
+				}
 			} else {
 				// single video
-				err = transcribeFile(inputPath, "./", apiKey, ctx, c)
+				err = transcribeFile(inputPath, "./", transcriber, ctx, c, encodeOpts)
 				if err != nil {
 					return err
 				}
@@ -93,14 +275,21 @@ func main() {
 	}
 }
 
-func transcribeDir(inputDirPath, apiKey string, ctx context.Context, c *cli.Command) error {
+func transcribeDir(inputDirPath string, transcriber Transcriber, ctx context.Context, c *cli.Command, encodeOpts EncodeOptions) (*dirSummary, error) {
 	// transcribes an entire directory
 	// slightly different logic from single file
 	// so gets its own function
 
 	files, err := os.ReadDir(inputDirPath)
 	if err != nil {
-		return fmt.Errorf("%v\nthere was an error while reading the directory %v", err.Error(), inputDirPath)
+		return nil, fmt.Errorf("%v\nthere was an error while reading the directory %v", err.Error(), inputDirPath)
+	}
+
+	var jobs []os.DirEntry
+	for _, file := range files {
+		if fileExt := filepath.Ext(file.Name()); slices.Contains(validVideoFormats, strings.ToLower(fileExt)) {
+			jobs = append(jobs, file)
+		}
 	}
 
 	// create a output folder to place all transcribed videos
@@ -108,118 +297,141 @@ func transcribeDir(inputDirPath, apiKey string, ctx context.Context, c *cli.Comm
 	os.RemoveAll(outputPath)
 	err = os.Mkdir(outputPath, 0644)
 	if err != nil {
-		return fmt.Errorf("%v\nthere was an error while making output directory", err.Error())
+		return nil, fmt.Errorf("%v\nthere was an error while making output directory", err.Error())
 	}
 
-	success := 0
-	spinner := spinner.New(spinner.CharSets[2], 100*time.Millisecond)
-	defer spinner.Stop()
-	spinner.Prefix = fmt.Sprintf("Transcoding video(s) %v of %v... ", success, len(files))
-	spinner.Start()
-
 	// create a tmp folder to place all files while program is running
 	tempDirPath, err := os.MkdirTemp("", "transcribe-")
 	if err != nil {
-		return errors.New("error while creating temp folder")
+		return nil, errors.New("error while creating temp folder")
 	}
 	defer os.RemoveAll(tempDirPath) // clean up the tmp files when program done
 
-	// loop through entire directory and transcribe each video
-	// use go routines fast af
+	concurrency := c.Int("concurrency")
+	if concurrency < 1 {
+		concurrency = runtime.NumCPU()
+	}
+	maxRetries := c.Int("max-retries")
+	retryBackoff := c.Duration("retry-backoff")
+
+	progress := NewProgressBoard()
+	progress.Start()
+
+	// bound how many files are in flight at once instead of spawning one
+	// goroutine per file, and aggregate results behind a mutex since
+	// multiple workers finish concurrently
+	summary := &dirSummary{Total: len(jobs)}
+	var mu sync.Mutex
+	sem := make(chan struct{}, concurrency)
 	var wg sync.WaitGroup
-	var errs []error
-	for _, file := range files {
-		fullPath := filepath.Join(inputDirPath, file.Name())
-		if fileExt := filepath.Ext(fullPath); !slices.Contains(validVideoFormats, strings.ToLower(fileExt)) {
-			continue
-		}
 
-		wg.Go(func() {
-			// run ffmpeg to convert input file to mp3
-			outputAudioPath := filepath.Join(tempDirPath, file.Name()+"_audio.mp3")
-			cmd := exec.Command(
-				"ffmpeg",
-				"-y",
-				"-i", fullPath,
-				outputAudioPath,
-			)
-			err = cmd.Run()
-			if err != nil {
-				errs = append(errs, fmt.Errorf("%v\nerror while converting %s to audio format", err.Error(), fullPath))
-				return
-			}
+	for _, file := range jobs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(file os.DirEntry) {
+			defer wg.Done()
+			defer func() { <-sem }()
 
-			structuredResponse, err := TranscribeVideo(ctx, apiKey, c, outputAudioPath)
-			if err != nil {
-				errs = append(errs, fmt.Errorf("%v\nerror while transcribing video", err.Error()))
-				return
-			}
+			err := transcribeDirEntry(ctx, inputDirPath, outputPath, tempDirPath, transcriber, file.Name(), c, maxRetries, retryBackoff, progress, encodeOpts)
 
-			strFilePath, err := GenerateSrtFile(&structuredResponse, tempDirPath)
+			mu.Lock()
+			defer mu.Unlock()
 			if err != nil {
-				errs = append(errs, fmt.Errorf("%v\nerror while saving srt file", err.Error()))
-				return
+				summary.Failed++
+				summary.Errors = append(summary.Errors, fmt.Errorf("%s: %w", file.Name(), err))
+			} else {
+				summary.Success++
 			}
+		}(file)
+	}
+	wg.Wait()
+	progress.Stop()
 
-			// now that we have the srt file, get ffmpeg to add subtitles
-			// to the original video file
-			finalVideoPath := filepath.Join(outputPath, "transcribed_"+file.Name())
-			tempVideoPath := filepath.Join(tempDirPath, finalVideoPath)
-			cmd = exec.Command("ffmpeg",
-				"-y",
-				"-i", fullPath,
-				"-vf",
-				"subtitles="+strFilePath,
-				tempVideoPath, // place in tmp folder
-			)
-			err = cmd.Run()
-			if err != nil {
-				errs = append(errs, fmt.Errorf("%v\nerror while adding subtitles to original video", err.Error()))
-				return
-			}
+	if len(summary.Errors) > 0 {
+		for _, e := range summary.Errors {
+			fmt.Println(e.Error())
+		}
+	}
+	fmt.Printf("finished %v of %v videos with %v error(s)\n", summary.Success, summary.Total, summary.Failed)
 
-			// now copy that video out of the tmp folder and place in root
-			// SUCCESS!
-			in, err := os.Open(tempVideoPath)
-			if err != nil {
-				errs = append(errs, fmt.Errorf("%v\nerror while copying tmp video to root directory", err.Error()))
-				return
-			}
-			defer in.Close()
+	return summary, nil
+}
 
-			out, err := os.Create(finalVideoPath)
-			if err != nil {
-				errs = append(errs, err)
-				return
-			}
-			defer out.Close()
+// transcribeDirEntry runs the full per-file pipeline (extract audio,
+// transcribe, optional grammar-check, export, subtitle burn-in, copy to
+// outputPath) for a single file in inputDirPath, reporting its stage to
+// progress as it goes.
+func transcribeDirEntry(ctx context.Context, inputDirPath, outputPath, tempDirPath string, transcriber Transcriber, filename string, c *cli.Command, maxRetries int, retryBackoff time.Duration, progress *ProgressBoard, encodeOpts EncodeOptions) error {
+	defer progress.Clear(filename)
+	fullPath := filepath.Join(inputDirPath, filename)
 
-			_, err = io.Copy(out, in)
-			if err != nil {
-				errs = append(errs, err)
-				return
-			}
+	progress.Set(filename, "extracting audio")
+	outputAudioPath, err := VideoToMp3(tempDirPath, fullPath, encodeOpts)
+	if err != nil {
+		return err
+	}
 
-			success++
-			spinner.Prefix = fmt.Sprintf("Transcoding video(s) %v of %v... ", success, len(files))
-		})
+	progress.Set(filename, "transcribing")
+	structuredResponse, err := TranscribeChunked(ctx, transcriber, outputAudioPath, tempDirPath, c.Duration("chunk-duration"), c.Duration("chunk-overlap"), c.String("stitch-strategy"), maxRetries, retryBackoff)
+	if err != nil {
+		return fmt.Errorf("%v\nerror while transcribing video", err.Error())
 	}
-	wg.Wait()
-	spinner.Stop()
 
-	if len(errs) > 0 {
-		for _, e := range errs {
-			fmt.Println(e.Error())
+	if c.Bool("grammar-check") {
+		progress.Set(filename, "grammar-checking")
+		checker, err := NewGrammarChecker(c.String("grammar-server"))
+		if err != nil {
+			return err
+		}
+		if err := ApplyGrammarCheck(&structuredResponse, checker); err != nil {
+			return err
 		}
-		return errors.New("there were errors")
 	}
 
-	fmt.Printf("finished %v videos wiht %v errors", success, len(errs))
+	baseName := strings.TrimSuffix(filename, filepath.Ext(filename))
+	if exportFormats := parseExportFormats(c.String("export")); len(exportFormats) > 0 {
+		progress.Set(filename, "exporting transcript")
+		if err := ExportTranscripts(&structuredResponse, outputPath, baseName, exportFormats); err != nil {
+			return err
+		}
+	}
 
-	return nil
+	progress.Set(filename, "generating subtitles")
+	format := c.String("format")
+	subsFilePath, err := generateSubtitleFile(&structuredResponse, filename, tempDirPath, c)
+	if err != nil {
+		return fmt.Errorf("%v\nerror while saving subtitle file", err.Error())
+	}
+
+	// now that we have the subtitle file, get ffmpeg to add subtitles
+	// to the original video file
+	progress.Set(filename, "burning subtitles")
+	finalVideoPath := filepath.Join(outputPath, "transcribed_"+filename)
+	tempVideoPath, err := ApplySubtitles(tempDirPath, fullPath, subsFilePath, format, encodeOpts)
+	if err != nil {
+		return err
+	}
+
+	// now copy that video out of the tmp folder and place in root
+	// SUCCESS!
+	progress.Set(filename, "finishing up")
+	in, err := os.Open(tempVideoPath)
+	if err != nil {
+		return fmt.Errorf("%v\nerror while copying tmp video to root directory", err.Error())
+	}
+	defer in.Close()
+
+	out, err := os.Create(finalVideoPath)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, in)
+	return err
 }
 
-func transcribeFile(inputPath, outputPath, apiKey string, ctx context.Context, c *cli.Command) error {
+func transcribeFile(inputPath, outputPath string, transcriber Transcriber, ctx context.Context, c *cli.Command, encodeOpts EncodeOptions) error {
 	// make sure its a valid video format
 	if fileExt := filepath.Ext(inputPath); !slices.Contains(validVideoFormats, strings.ToLower(fileExt)) {
 		return fmt.Errorf(
@@ -243,45 +455,49 @@ func transcribeFile(inputPath, outputPath, apiKey string, ctx context.Context, c
 	defer os.RemoveAll(tempDirPath) // clean up the tmp files when program done
 
 	// run ffmpeg to convert input file to mp3
-	outputAudioPath := filepath.Join(tempDirPath, "audio.mp3")
-	cmd := exec.Command(
-		"ffmpeg",
-		"-y", // this will overwrite the output video if already exists
-		"-i", inputPath,
-		outputAudioPath,
-	)
-	err = cmd.Run()
+	outputAudioPath, err := VideoToMp3(tempDirPath, inputPath, encodeOpts)
 	if err != nil {
-		return fmt.Errorf("%v\nerror while converting %s to audio format", err.Error(), inputPath)
+		return err
 	}
 
 	spinner.Prefix = "Transcribing audio... "
-	structuredResponse, err := TranscribeVideo(ctx, apiKey, c, outputAudioPath)
+	structuredResponse, err := TranscribeChunked(ctx, transcriber, outputAudioPath, tempDirPath, c.Duration("chunk-duration"), c.Duration("chunk-overlap"), c.String("stitch-strategy"), c.Int("max-retries"), c.Duration("retry-backoff"))
 	if err != nil {
 		return fmt.Errorf("%v\nerror while transcribing video", err.Error())
 	}
 
-	strFilePath, err := GenerateSrtFile(&structuredResponse, tempDirPath)
+	if c.Bool("grammar-check") {
+		spinner.Prefix = "Grammar-checking transcript... "
+		checker, err := NewGrammarChecker(c.String("grammar-server"))
+		if err != nil {
+			return err
+		}
+		if err := ApplyGrammarCheck(&structuredResponse, checker); err != nil {
+			return err
+		}
+	}
+
+	baseName := strings.TrimSuffix(filepath.Base(inputPath), filepath.Ext(inputPath))
+	if exportFormats := parseExportFormats(c.String("export")); len(exportFormats) > 0 {
+		if err := ExportTranscripts(&structuredResponse, outputPath, baseName, exportFormats); err != nil {
+			return fmt.Errorf("%v\nerror while exporting transcript", err.Error())
+		}
+	}
+
+	format := c.String("format")
+	subsFilePath, err := generateSubtitleFile(&structuredResponse, filepath.Base(inputPath), tempDirPath, c)
 	if err != nil {
-		return fmt.Errorf("%v\nerror while saving srt file", err.Error())
+		return fmt.Errorf("%v\nerror while saving subtitle file", err.Error())
 	}
 
 	spinner.Prefix = "Adding subtitles overlay... "
 
-	// now that we have the srt file, get ffmpeg to add subtitles
+	// now that we have the subtitle file, get ffmpeg to add subtitles
 	// to the original video file
 	finalVideoPath := "transcribed_" + inputPath
-	tempVideoPath := filepath.Join(tempDirPath, finalVideoPath)
-	cmd = exec.Command("ffmpeg",
-		"-y",
-		"-i", inputPath,
-		"-vf",
-		"subtitles="+strFilePath,
-		tempVideoPath, // place in tmp folder
-	)
-	err = cmd.Run()
+	tempVideoPath, err := ApplySubtitles(tempDirPath, inputPath, subsFilePath, format, encodeOpts)
 	if err != nil {
-		return fmt.Errorf("%v\nerror while adding subtitles to original video", err.Error())
+		return err
 	}
 
 	// now copy that video out of the tmp folder and place in root
@@ -306,3 +522,79 @@ func transcribeFile(inputPath, outputPath, apiKey string, ctx context.Context, c
 	spinner.FinalMSG = fmt.Sprintf("Transcription completed in %v seconds\n", fmt.Sprintf("%.2f", time.Since(startTime).Seconds()))
 	return nil
 }
+
+// dryRunFfmpeg prints the ffmpeg commands --hwaccel/--crf/--preset compose
+// for every input video without probing, transcribing, or exporting
+// anything, since --dry-run-ffmpeg only cares about the commands
+// themselves. It uses a placeholder subtitle path since no real subtitle
+// file exists yet at this point in the pipeline.
+func dryRunFfmpeg(inputPath string, c *cli.Command, encodeOpts EncodeOptions) error {
+	info, err := os.Stat(inputPath)
+	if err != nil {
+		return errors.New("unable to read input path: " + inputPath)
+	}
+
+	var files []string
+	if info.IsDir() {
+		entries, err := os.ReadDir(inputPath)
+		if err != nil {
+			return fmt.Errorf("%v\nthere was an error while reading the directory %v", err.Error(), inputPath)
+		}
+		for _, entry := range entries {
+			if slices.Contains(validVideoFormats, strings.ToLower(filepath.Ext(entry.Name()))) {
+				files = append(files, filepath.Join(inputPath, entry.Name()))
+			}
+		}
+	} else {
+		files = append(files, inputPath)
+	}
+
+	format := c.String("format")
+	subsExt := ".srt"
+	if format == "ass" {
+		subsExt = ".ass"
+	}
+
+	for _, file := range files {
+		if _, err := VideoToMp3(os.TempDir(), file, encodeOpts); err != nil {
+			return err
+		}
+
+		placeholderSubsPath := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)) + subsExt
+		if _, err := ApplySubtitles(os.TempDir(), file, placeholderSubsPath, format, encodeOpts); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// generateSubtitleFile writes the transcript out in whatever format
+// --format asks for, returning the escaped path ffmpeg expects.
+func generateSubtitleFile(transcript *Schema, filename, tempDirPath string, c *cli.Command) (string, error) {
+	if c.String("format") == "ass" {
+		style := AssStyle{
+			Font:         c.String("font"),
+			FontSize:     c.Int("font-size"),
+			PrimaryColor: c.String("primary-color"),
+			OutlineColor: c.String("outline-color"),
+			Position:     c.String("position"),
+		}
+		return GenerateAssFile(transcript, filename, tempDirPath, style)
+	}
+
+	return GenerateSrtFile(transcript, filename, tempDirPath)
+}
+
+// parseExportFormats splits and trims a comma-separated --export value,
+// dropping empty entries so "" and "srt, vtt" both behave sensibly.
+func parseExportFormats(export string) []string {
+	var formats []string
+	for _, format := range strings.Split(export, ",") {
+		format = strings.TrimSpace(format)
+		if format != "" {
+			formats = append(formats, format)
+		}
+	}
+	return formats
+}
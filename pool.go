@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// dirSummary is the structured result of transcribeDir, returned instead of
+// just printing totals so callers can inspect per-file failures.
+type dirSummary struct {
+	Total   int
+	Success int
+	Failed  int
+	Errors  []error
+}
+
+// transcribeWithRetry calls transcriber.Transcribe and retries on failure
+// with exponential backoff, since backends like Gemini frequently return
+// 429/503 on large batches.
+func transcribeWithRetry(ctx context.Context, transcriber Transcriber, audioPath string, maxRetries int, backoff time.Duration) (Schema, error) {
+	var lastErr error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff * time.Duration(uint(1)<<uint(attempt-1)))
+		}
+
+		schema, err := transcriber.Transcribe(ctx, audioPath)
+		if err == nil {
+			return schema, nil
+		}
+		lastErr = err
+	}
+
+	return Schema{}, fmt.Errorf("%v\ntranscription failed after %d attempt(s)", lastErr.Error(), maxRetries+1)
+}
+
+// ProgressBoard renders one status line per in-flight file (e.g.
+// "extracting audio", "transcribing", "burning subtitles") so a bounded
+// worker pool transcoding several videos at once doesn't have to share a
+// single spinner line across goroutines.
+type ProgressBoard struct {
+	mu       sync.Mutex
+	order    []string
+	lines    map[string]string
+	done     chan struct{}
+	rendered int
+}
+
+func NewProgressBoard() *ProgressBoard {
+	return &ProgressBoard{
+		lines: make(map[string]string),
+		done:  make(chan struct{}),
+	}
+}
+
+func (p *ProgressBoard) Start() {
+	go func() {
+		ticker := time.NewTicker(150 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-p.done:
+				p.render()
+				return
+			case <-ticker.C:
+				p.render()
+			}
+		}
+	}()
+}
+
+// Stop halts the render loop. Callers must not call Set/Clear after Stop.
+func (p *ProgressBoard) Stop() {
+	close(p.done)
+}
+
+// Set updates (or adds) the status line for a file.
+func (p *ProgressBoard) Set(name, stage string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.lines[name]; !ok {
+		p.order = append(p.order, name)
+	}
+	p.lines[name] = stage
+}
+
+// Clear removes a file's line once it finishes, successfully or not.
+func (p *ProgressBoard) Clear(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.lines, name)
+	for i, n := range p.order {
+		if n == name {
+			p.order = append(p.order[:i], p.order[i+1:]...)
+			break
+		}
+	}
+}
+
+func (p *ProgressBoard) render() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// move the cursor back up over whatever we rendered last pass
+	for i := 0; i < p.rendered; i++ {
+		fmt.Fprint(os.Stderr, "\033[1A\033[2K")
+	}
+
+	for _, name := range p.order {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", name, p.lines[name])
+	}
+	p.rendered = len(p.order)
+}
@@ -5,7 +5,6 @@ import (
 	"math"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
 )
 
@@ -13,24 +12,14 @@ func GenerateSrtFile(transcript *Schema, filename, tempDirPath string) (string,
 	// take in the gemini response and create a
 	// valid formatted SRT file
 
-	var sb strings.Builder
-	for i, v := range transcript.Segments {
-		// NUM
-		num := strconv.Itoa(i + 1)
-		sb.WriteString(num + "\n")
-
-		// TIMESTAMP
-		start := getTimestamp(v.Start)
-		end := getTimestamp(v.End)
-		sb.WriteString(fmt.Sprintf("%v --> %v\n", start, end))
-
-		// TEXT
-		sb.WriteString("- " + v.Text + "\n")
+	contents, err := (srtWriter{}).Render(transcript)
+	if err != nil {
+		return "", err
 	}
 
 	// take the SRT formatted string and save
 	outputPath := filepath.Join(tempDirPath, filename+"_subs.srt")
-	err := os.WriteFile(outputPath, []byte(sb.String()), 0666)
+	err = os.WriteFile(outputPath, contents, 0666)
 	if err != nil {
 		return "", err
 	}